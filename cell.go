@@ -0,0 +1,49 @@
+package tableview
+
+import "github.com/gdamore/tcell/v2"
+
+// Cell holds one table cell: its text, an optional style override, and
+// an opaque reference an application can attach and retrieve later via
+// SetCellReference/GetCellReference.
+type Cell struct {
+	Text      string
+	Style     *tcell.Style // nil: no override, column/row defaults apply
+	Reference interface{}
+}
+
+// SetCellStyle sets a per-cell style override, applied on top of any
+// row style from SetRowStyleFunc.  row and column are original (not
+// display) indices, as with SetCell.
+func (t *TableView) SetCellStyle(row, col int, style tcell.Style) {
+	if row < 0 || row >= len(t.data) || col < 0 || col >= len(t.data[row]) {
+		return // TODO show return error
+	}
+	t.data[row][col].Style = &style
+	t.fillTable()
+}
+
+// SetCellReference attaches an opaque value to a cell, retrievable with
+// GetCellReference.  row and column are original (not display) indices.
+func (t *TableView) SetCellReference(row, col int, ref interface{}) {
+	if row < 0 || row >= len(t.data) || col < 0 || col >= len(t.data[row]) {
+		return // TODO show return error
+	}
+	t.data[row][col].Reference = ref
+}
+
+// GetCellReference returns the value attached to a cell by
+// SetCellReference, or nil if none was set.
+func (t *TableView) GetCellReference(row, col int) interface{} {
+	if row < 0 || row >= len(t.data) || col < 0 || col >= len(t.data[row]) {
+		return nil
+	}
+	return t.data[row][col].Reference
+}
+
+// SetRowStyleFunc sets a function computing a base style for each row,
+// keyed by original row index, applied before any per-cell override
+// from SetCellStyle.  Pass nil to go back to the default style.
+func (t *TableView) SetRowStyleFunc(f func(row int) tcell.Style) {
+	t.rowStyleFunc = f
+	t.fillTable()
+}
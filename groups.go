@@ -0,0 +1,135 @@
+package tableview
+
+import "fmt"
+
+// rowGroup is a contiguous range of original row indices joined by
+// JoinRows: its members always move, filter, search and sort as a unit.
+// members[0] is the representative: sorting compares it and search jumps
+// to it, while a filter keeps the whole group if any member matches.
+type rowGroup struct {
+	members []int
+}
+
+// rowUnit is either a lone row or a whole rowGroup, used to apply
+// filterData/sortRows at the right granularity: rep is the row whose
+// content decides where the unit sorts, and rows is every original row
+// index the unit carries along with it (the unit matches a filter if any
+// of rows does).
+type rowUnit struct {
+	rep  int
+	rows []int
+}
+
+// rowUnits splits every row of t.data into rowUnits, joined rows first
+// appearing together at their group's lowest member index.
+func (t *TableView) rowUnits() []rowUnit {
+	groupOf := make(map[int]int, len(t.groups))
+	for gi, g := range t.groups {
+		for _, m := range g.members {
+			groupOf[m] = gi
+		}
+	}
+	seen := make(map[int]bool, len(t.groups))
+	units := make([]rowUnit, 0, len(t.data))
+	for i := 0; i < len(t.data); i++ {
+		if gi, ok := groupOf[i]; ok {
+			if seen[gi] {
+				continue
+			}
+			seen[gi] = true
+			units = append(units, rowUnit{rep: t.groups[gi].members[0], rows: t.groups[gi].members})
+			continue
+		}
+		units = append(units, rowUnit{rep: i, rows: []int{i}})
+	}
+	return units
+}
+
+// orderedUnits splits the current t.orderRows into rowUnits, assuming
+// (as filterData guarantees) that a group's members are contiguous and
+// in member order wherever they appear.
+func (t *TableView) orderedUnits() []rowUnit {
+	units := make([]rowUnit, 0, len(t.orderRows))
+	for i := 0; i < len(t.orderRows); {
+		row := t.orderRows[i]
+		if g, pos, ok := t.groupMembership(row); ok && pos == 0 && i+len(g.members) <= len(t.orderRows) {
+			rows := append([]int(nil), t.orderRows[i:i+len(g.members)]...)
+			units = append(units, rowUnit{rep: g.members[0], rows: rows})
+			i += len(g.members)
+			continue
+		}
+		units = append(units, rowUnit{rep: row, rows: []int{row}})
+		i++
+	}
+	return units
+}
+
+// groupMembership returns the group row belongs to and its position
+// within it (0 is the representative), or ok=false if row isn't grouped.
+func (t *TableView) groupMembership(row int) (g rowGroup, pos int, ok bool) {
+	for _, grp := range t.groups {
+		for i, m := range grp.members {
+			if m == row {
+				return grp, i, true
+			}
+		}
+	}
+	return rowGroup{}, 0, false
+}
+
+// groupRepresentative returns row's group representative and true if
+// row belongs to a group, or row itself and false otherwise.
+func (t *TableView) groupRepresentative(row int) (int, bool) {
+	if g, _, ok := t.groupMembership(row); ok {
+		return g.members[0], true
+	}
+	return row, false
+}
+
+// representativePosition maps idx, the display position of row within
+// t.orderRows, back to the display position of row's group
+// representative (or idx itself, if row isn't grouped).
+func (t *TableView) representativePosition(idx, row int) int {
+	if _, pos, ok := t.groupMembership(row); ok {
+		return idx - pos
+	}
+	return idx
+}
+
+// JoinRows joins the contiguous original rows startRow..endRow (both
+// inclusive) into a group: filterData, search and the column-mode sort
+// will treat them as a single unit from now on, keyed on startRow.
+func (t *TableView) JoinRows(startRow, endRow int) error {
+	if startRow < 0 || endRow >= len(t.data) || startRow > endRow {
+		return fmt.Errorf("tableview: invalid row range %d-%d", startRow, endRow)
+	}
+	for _, g := range t.groups {
+		for _, m := range g.members {
+			if m >= startRow && m <= endRow {
+				return fmt.Errorf("tableview: row %d is already part of a group", m)
+			}
+		}
+	}
+	members := make([]int, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow; row++ {
+		members = append(members, row)
+	}
+	t.groups = append(t.groups, rowGroup{members: members})
+	t.filterData()
+	return nil
+}
+
+// UnjoinRows dissolves the group that row belongs to; row can be any of
+// its members, not just the representative.
+func (t *TableView) UnjoinRows(row int) error {
+	for i, g := range t.groups {
+		for _, m := range g.members {
+			if m == row {
+				t.groups = append(t.groups[:i:i], t.groups[i+1:]...)
+				t.filterData()
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("tableview: row %d is not part of any group", row)
+}
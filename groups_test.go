@@ -0,0 +1,16 @@
+package tableview
+
+import "testing"
+
+// Re-filling a table with fewer rows must drop any groups from the old
+// row set, or filterData panics walking their now out-of-range members.
+func TestFillCellsClearsGroupsOnRowCountChange(t *testing.T) {
+	tv := NewTableView()
+	tv.FillTable([]string{"a"}, [][]string{{"0"}, {"1"}, {"2"}, {"3"}})
+	if err := tv.JoinRows(1, 3); err != nil {
+		t.Fatalf("JoinRows: %v", err)
+	}
+
+	tv.FillTable([]string{"a"}, [][]string{{"0"}, {"1"}})
+	tv.filterData() // must not panic on the stale group's members
+}
@@ -0,0 +1,64 @@
+package tableview
+
+import "sort"
+
+// toggleMark marks or unmarks the row at original index row.
+func (t *TableView) toggleMark(row int) {
+	if t.marks == nil {
+		t.marks = make(map[int]bool)
+	}
+	if t.marks[row] {
+		delete(t.marks, row)
+	} else {
+		t.marks[row] = true
+	}
+}
+
+// SelectAll marks every row currently visible (i.e. passing the active
+// filter).
+func (t *TableView) SelectAll() {
+	if t.marks == nil {
+		t.marks = make(map[int]bool)
+	}
+	for _, row := range t.orderRows {
+		t.marks[row] = true
+	}
+	t.fillTable()
+}
+
+// ClearMarks unmarks every row.
+func (t *TableView) ClearMarks() {
+	t.marks = nil
+	t.fillTable()
+}
+
+// markedOrCurrentRows returns the original indices of all marked rows,
+// sorted, falling back to []int{current} when nothing is marked.
+func (t *TableView) markedOrCurrentRows(current int) []int {
+	if len(t.marks) == 0 {
+		return []int{current}
+	}
+	rows := make([]int, 0, len(t.marks))
+	for row := range t.marks {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// NewBulkCommand sets the function to be executed when the given key is
+// pressed.  Unlike NewCommand, action receives the original indices of
+// every marked row, or just the current row when nothing is marked.
+func (t *TableView) NewBulkCommand(ch rune, text string, action func(rows []int)) *Command {
+	command := Command{}
+	command.table = t
+	command.ch = ch
+	command.text = text
+	command.bulk = action
+	command.enabled = true
+	t.commands = append(t.commands, &command)
+	if !t.selectCols {
+		t.updateLegend()
+	}
+	return &command
+}
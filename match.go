@@ -0,0 +1,133 @@
+package tableview
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MatchMode selects the algorithm TableView uses to decide whether a row
+// matches the active filter or search text.
+type MatchMode int
+
+// Matching modes accepted by SetMatcher.
+const (
+	MatchSubstring MatchMode = iota // plain case-insensitive substring match
+	MatchFuzzy                      // fzf-style fuzzy match, ranked by score
+	MatchRegex                      // filter or search text is compiled as a regular expression
+)
+
+// SetMatcher selects how filterData and search decide whether a row
+// matches, and re-applies the active filter under the new mode.
+func (t *TableView) SetMatcher(mode MatchMode) {
+	t.matchMode = mode
+	t.filterRegexp = nil
+	t.filterRegexpSrc = ""
+	t.filterData()
+}
+
+// matchCell reports whether candidate matches query under the table's
+// active match mode.  The returned score ranks matches for MatchFuzzy
+// (higher is better); it is always 0 for the other modes, which don't
+// rank their matches.
+func (t *TableView) matchCell(query, candidate string) (bool, int) {
+	switch t.matchMode {
+	case MatchFuzzy:
+		return fuzzyMatch(query, candidate)
+	case MatchRegex:
+		if t.filterRegexp == nil || t.filterRegexpSrc != query {
+			re, err := regexp.Compile(query)
+			if err != nil {
+				t.filterRegexp = nil
+				t.filterRegexpSrc = query
+				return false, 0
+			}
+			t.filterRegexp = re
+			t.filterRegexpSrc = query
+		}
+		if t.filterRegexp == nil {
+			return false, 0
+		}
+		return t.filterRegexp.MatchString(candidate), 0
+	default:
+		return strings.Contains(strings.ToLower(candidate), strings.ToLower(query)), 0
+	}
+}
+
+// Scoring weights used by fuzzyMatch, loosely following fzf's algorithm.
+const (
+	fuzzyScoreMatch       = 16 // awarded for each query rune matched
+	fuzzyScoreBoundary    = 8  // bonus for matching right at a word start
+	fuzzyScoreConsecutive = 12 // bonus for matching immediately after the previous one
+)
+
+// fuzzyMatch reports whether every rune of query appears in candidate, in
+// order, and returns a score rewarding matches at word boundaries (after
+// '/', '_', '-', '.', space, or a camelCase transition), consecutive
+// runs, and matches that start earlier in candidate.
+func fuzzyMatch(query, candidate string) (bool, int) {
+	if query == "" {
+		return true, 0
+	}
+
+	q := []rune(strings.ToLower(query))
+	c := []rune(candidate)
+	cl := []rune(strings.ToLower(candidate))
+	n, m := len(q), len(c)
+	if n > m {
+		return false, 0
+	}
+
+	isBoundary := func(i int) bool {
+		if i == 0 {
+			return true
+		}
+		switch c[i-1] {
+		case '/', '_', '-', ' ', '.':
+			return true
+		}
+		return unicode.IsLower(c[i-1]) && unicode.IsUpper(c[i])
+	}
+
+	// dp[j] is the best score found so far of matching q[:j] within the
+	// candidate runes walked up to the current one; lastPos[j] is the
+	// candidate index that best match ended at, so a later rune can tell
+	// whether it is immediately adjacent to it (a true consecutive run)
+	// rather than merely "somewhere after it".  Both are updated in place,
+	// walking j from high to low so that dp[j-1] and lastPos[j-1] still
+	// hold last rune's values when read.
+	const unreached = -1 << 30
+	const noPos = -2 // never equal to i-1, since i >= 0
+	dp := make([]int, n+1)
+	lastPos := make([]int, n+1)
+	for j := 0; j <= n; j++ {
+		lastPos[j] = noPos
+	}
+	for j := 1; j <= n; j++ {
+		dp[j] = unreached
+	}
+
+	for i := 0; i < m; i++ {
+		for j := n; j >= 1; j-- {
+			if dp[j-1] == unreached || cl[i] != q[j-1] {
+				continue
+			}
+			score := dp[j-1] + fuzzyScoreMatch - i
+			if isBoundary(i) {
+				score += fuzzyScoreBoundary
+			}
+			if lastPos[j-1] == i-1 {
+				score += fuzzyScoreConsecutive
+			}
+			if score > dp[j] {
+				dp[j] = score
+				lastPos[j] = i
+			}
+		}
+	}
+
+	if dp[n] == unreached {
+		return false, 0
+	}
+	return true, dp[n]
+}
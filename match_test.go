@@ -0,0 +1,22 @@
+package tableview
+
+import "testing"
+
+// A genuinely consecutive run ("bc" adjacent in the candidate) must
+// outscore the same letters matched with gaps between them.
+func TestFuzzyMatchPrefersConsecutiveRuns(t *testing.T) {
+	okGap, scoreGap := fuzzyMatch("abc", "xa_b_c")
+	okRun, scoreRun := fuzzyMatch("abc", "xa_bc")
+	if !okGap || !okRun {
+		t.Fatalf("expected both candidates to match, got okGap=%v okRun=%v", okGap, okRun)
+	}
+	if scoreRun <= scoreGap {
+		t.Errorf("consecutive match scored %d, want more than the gapped match's %d", scoreRun, scoreGap)
+	}
+}
+
+func TestFuzzyMatchNoMatch(t *testing.T) {
+	if ok, _ := fuzzyMatch("xyz", "abc"); ok {
+		t.Errorf("expected no match")
+	}
+}
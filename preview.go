@@ -0,0 +1,114 @@
+package tableview
+
+import "github.com/rivo/tview"
+
+// PreviewPosition selects where the preview pane is placed relative to
+// the table.
+type PreviewPosition int
+
+// Positions accepted by PreviewOpts.Position.
+const (
+	PreviewRight PreviewPosition = iota
+	PreviewBottom
+)
+
+// PreviewOpts controls the placement, size and behaviour of the preview
+// pane set up by SetPreview.
+type PreviewOpts struct {
+	Position PreviewPosition
+	Size     int  // fixed size, in cells, along the split axis
+	Percent  int  // if > 0, size as a percentage of the table area instead of Size
+	Wrap     bool // wrap long lines instead of scrolling horizontally
+}
+
+// SetPreview installs a preview pane next to the table.  On every
+// selection change, f is called with the original row index and its
+// result is shown in the pane.
+func (t *TableView) SetPreview(f func(row int) string, opts PreviewOpts) {
+	t.preview = f
+	t.previewOpts = opts
+	if t.previewPane == nil {
+		t.previewPane = tview.NewTextView()
+		t.previewPane.SetDynamicColors(true)
+	}
+	t.previewPane.SetWrap(opts.Wrap)
+	t.previewVisible = true
+	t.layoutPreview()
+	t.refreshPreview()
+}
+
+// TogglePreview returns a Command, bound to ch, that shows or hides the
+// preview pane set up by SetPreview.
+func (t *TableView) TogglePreview(ch rune) *Command {
+	return t.NewCommand(ch, "preview", func(row int) {
+		t.previewVisible = !t.previewVisible
+		t.layoutPreview()
+	})
+}
+
+// layoutPreview rebuilds tableFlex's children to reflect previewOpts and
+// previewVisible.  tview.Flex recomputes the actual cell sizes from
+// these proportions on every resize, so there is nothing else to do on
+// a terminal resize.
+func (t *TableView) layoutPreview() {
+	t.tableFlex.Clear()
+	if t.preview == nil || t.previewPane == nil || !t.previewVisible {
+		t.tableFlex.SetDirection(tview.FlexColumn)
+		t.tableFlex.AddItem(t.table, 0, 1, true)
+		return
+	}
+
+	if t.previewOpts.Position == PreviewBottom {
+		t.tableFlex.SetDirection(tview.FlexRow)
+	} else {
+		t.tableFlex.SetDirection(tview.FlexColumn)
+	}
+
+	if t.previewOpts.Percent > 0 {
+		previewProportion := t.previewOpts.Percent
+		tableProportion := 100 - previewProportion
+		if tableProportion < 1 {
+			tableProportion = 1
+		}
+		t.tableFlex.AddItem(t.table, 0, tableProportion, true)
+		t.tableFlex.AddItem(t.previewPane, 0, previewProportion, false)
+		return
+	}
+
+	size := t.previewOpts.Size
+	if size <= 0 {
+		size = 10
+	}
+	t.tableFlex.AddItem(t.table, 0, 1, true)
+	t.tableFlex.AddItem(t.previewPane, size, 0, false)
+}
+
+// refreshPreview re-renders the preview pane for the currently selected
+// row.  It is a no-op when no preview has been set up.
+func (t *TableView) refreshPreview() {
+	if t.preview == nil || t.previewPane == nil {
+		return
+	}
+	row, _ := t.table.GetSelection()
+	row--
+	if row < 0 || row >= len(t.orderRows) {
+		t.previewPane.SetText("")
+		return
+	}
+	t.previewPane.SetText(t.preview(t.orderRows[row]))
+	t.previewPane.ScrollToBeginning()
+}
+
+// scrollPreview moves the preview pane's scroll position by delta lines
+// without moving the table's selection.
+func (t *TableView) scrollPreview(delta int) {
+	if t.previewPane == nil {
+		return
+	}
+	row, col := t.previewPane.GetScrollOffset()
+	row += delta
+	if row < 0 {
+		row = 0
+	}
+	t.previewPane.ScrollTo(row, col)
+}
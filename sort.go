@@ -0,0 +1,185 @@
+package tableview
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type columnKind int
+
+const (
+	columnKindAuto columnKind = iota // not configured: guess int/float, else compare as strings
+	columnKindString
+	columnKindInt
+	columnKindFloat
+	columnKindDuration
+	columnKindTime
+	columnKindCustom
+)
+
+// ColumnType tells SetColumnType how to compare a column's values when
+// sorting.  Build one with ColumnTypeString, ColumnTypeInt,
+// ColumnTypeFloat, ColumnTypeDuration, ColumnTypeTime or
+// ColumnTypeCustom; the zero value auto-detects ints and floats and
+// falls back to a lexicographic compare.
+type ColumnType struct {
+	kind   columnKind
+	layout string                 // time layout, used when kind == columnKindTime
+	less   func(a, b string) bool // used when kind == columnKindCustom
+}
+
+// Column types usable with SetColumnType.
+var (
+	ColumnTypeString   = ColumnType{kind: columnKindString}
+	ColumnTypeInt      = ColumnType{kind: columnKindInt}
+	ColumnTypeFloat    = ColumnType{kind: columnKindFloat}
+	ColumnTypeDuration = ColumnType{kind: columnKindDuration}
+)
+
+// ColumnTypeTime returns a ColumnType that parses values with
+// time.Parse(layout, value) before comparing them.
+func ColumnTypeTime(layout string) ColumnType {
+	return ColumnType{kind: columnKindTime, layout: layout}
+}
+
+// ColumnTypeCustom returns a ColumnType that defers to less to compare
+// two raw cell values.
+func ColumnTypeCustom(less func(a, b string) bool) ColumnType {
+	return ColumnType{kind: columnKindCustom, less: less}
+}
+
+// lessValues reports whether a sorts before b under ct.  Values that
+// can't be parsed as ct expects fall back to a plain string compare.
+func (ct ColumnType) lessValues(a, b string) bool {
+	switch ct.kind {
+	case columnKindInt, columnKindAuto:
+		ai, aerr := strconv.ParseInt(strings.TrimSpace(a), 10, 64)
+		bi, berr := strconv.ParseInt(strings.TrimSpace(b), 10, 64)
+		if aerr == nil && berr == nil {
+			return ai < bi
+		}
+		if ct.kind == columnKindInt {
+			break
+		}
+		fallthrough
+	case columnKindFloat:
+		af, aerr := strconv.ParseFloat(strings.TrimSpace(a), 64)
+		bf, berr := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if aerr == nil && berr == nil {
+			return af < bf
+		}
+	case columnKindDuration:
+		ad, aerr := time.ParseDuration(strings.TrimSpace(a))
+		bd, berr := time.ParseDuration(strings.TrimSpace(b))
+		if aerr == nil && berr == nil {
+			return ad < bd
+		}
+	case columnKindTime:
+		at, aerr := time.Parse(ct.layout, a)
+		bt, berr := time.Parse(ct.layout, b)
+		if aerr == nil && berr == nil {
+			return at.Before(bt)
+		}
+	case columnKindCustom:
+		if ct.less != nil {
+			return ct.less(a, b)
+		}
+	}
+	return a < b
+}
+
+// SetColumnType declares how column col's values should be compared
+// when sorting, overriding the auto-detection SetColumnType-less
+// columns fall back to.
+func (t *TableView) SetColumnType(col int, ct ColumnType) {
+	if col < 0 || col >= len(t.columns) {
+		return // TODO show return error
+	}
+	if len(t.columnTypes) < len(t.columns) {
+		t.columnTypes = append(t.columnTypes, make([]ColumnType, len(t.columns)-len(t.columnTypes))...)
+	}
+	t.columnTypes[col] = ct
+}
+
+// columnType returns the ColumnType declared for col, or the
+// auto-detecting zero value if none was set.
+func (t *TableView) columnType(col int) ColumnType {
+	if col < 0 || col >= len(t.columnTypes) {
+		return ColumnType{}
+	}
+	return t.columnTypes[col]
+}
+
+// sortKey is one entry of TableView.sortKeys: sort by column col,
+// descending if desc.
+type sortKey struct {
+	col  int
+	desc bool
+}
+
+// sortKeyIndex returns col's position in t.sortKeys (0 is the primary
+// key), or -1 if col isn't part of the active sort.
+func (t *TableView) sortKeyIndex(col int) int {
+	for i, k := range t.sortKeys {
+		if k.col == col {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleSort is the column-mode "s" handler: pressing it on the current
+// primary sort column flips its direction, and pressing it on any other
+// column makes that column the new primary key, ascending, pushing the
+// previous keys behind it as secondary sort keys.
+func (t *TableView) toggleSort(col int) {
+	if len(t.sortKeys) > 0 && t.sortKeys[0].col == col {
+		t.sortKeys[0].desc = !t.sortKeys[0].desc
+	} else {
+		rest := t.sortKeys[:0]
+		for _, k := range t.sortKeys {
+			if k.col != col {
+				rest = append(rest, k)
+			}
+		}
+		t.sortKeys = append([]sortKey{{col: col}}, rest...)
+	}
+	t.sortRows()
+	t.fillTable()
+}
+
+// sortRows reorders t.orderRows according to the active sort keys,
+// primary key first, comparing each joined group of rows (see JoinRows)
+// by its representative and keeping the group's internal order intact.
+// Equal units keep their relative order. It is a no-op when no sort is
+// active.
+func (t *TableView) sortRows() {
+	if len(t.sortKeys) == 0 {
+		return
+	}
+	units := t.orderedUnits()
+	sort.SliceStable(units, func(a, b int) bool {
+		ra, rb := units[a].rep, units[b].rep
+		for _, k := range t.sortKeys {
+			va := t.data[ra][k.col].Text
+			vb := t.data[rb][k.col].Text
+			if k.desc {
+				va, vb = vb, va
+			}
+			ct := t.columnType(k.col)
+			if ct.lessValues(va, vb) {
+				return true
+			}
+			if ct.lessValues(vb, va) {
+				return false
+			}
+		}
+		return false
+	})
+	t.orderRows = t.orderRows[:0]
+	for _, u := range units {
+		t.orderRows = append(t.orderRows, u.rows...)
+	}
+}
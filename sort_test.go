@@ -0,0 +1,14 @@
+package tableview
+
+import "testing"
+
+// Re-filling a table with fewer columns must drop any active sort keys,
+// or sortRows panics indexing the dropped columns.
+func TestFillCellsClearsSortKeysOnColumnCountChange(t *testing.T) {
+	tv := NewTableView()
+	tv.FillTable([]string{"a", "b", "c"}, [][]string{{"1", "x", "z"}, {"1", "y", "z"}})
+	tv.toggleSort(2)
+
+	tv.FillTable([]string{"a"}, [][]string{{"1"}, {"1"}})
+	tv.filterData() // must not panic indexing the dropped columns
+}
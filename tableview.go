@@ -6,9 +6,9 @@ package tableview
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
-	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -19,6 +19,7 @@ type Command struct {
 	ch      rune
 	text    string
 	action  func(row int)
+	bulk    func(rows []int) // set instead of action by NewBulkCommand
 	enabled bool
 }
 
@@ -34,23 +35,35 @@ func (c *Command) Enable() {
 
 // TableView holds a description of one table to be displayed
 type TableView struct {
-	app          *Application
-	ID           int // index of this table in parent Application's "tables"
-	flex         *tview.Flex
-	table        *tview.Table
-	columns      []string
-	data         [][]string
-	expansions   []int
-	aligns       []int
-	filter       string // active filter.  Used to regenerate orderRows
-	sortBy       int    // column to sort by
-	orderRows    []int  // rows to show, and in which order (generated from filter and sortBy)
-	orderCols    []int  // columns to show, and in which order
-	selectCols   bool   // selecting columns instead of rows
-	commands     []*Command
-	legend       *tview.TextView
-	lastLine     tview.Primitive
-	inputCapture func(k tcell.Key, r rune, row int) bool
+	app             *Application
+	ID              int // index of this table in parent Application's "tables"
+	flex            *tview.Flex
+	tableFlex       *tview.Flex // holds table and, if active, the preview pane
+	table           *tview.Table
+	preview         func(row int) string
+	previewOpts     PreviewOpts
+	previewPane     *tview.TextView
+	previewVisible  bool
+	columns         []string
+	data            [][]Cell
+	rowStyleFunc    func(row int) tcell.Style // base style for a row, keyed by original row index
+	expansions      []int
+	aligns          []int
+	filter          string // active filter.  Used to regenerate orderRows
+	matchMode       MatchMode
+	filterRegexp    *regexp.Regexp // last filter or search text compiled, valid while matchMode is MatchRegex
+	filterRegexpSrc string         // filter or search text the compiled regexp above was built from
+	columnTypes     []ColumnType   // per-column comparator, set via SetColumnType
+	sortKeys        []sortKey      // active sort, primary key first; empty means input/filter order
+	orderRows       []int          // rows to show, and in which order (generated from filter and sortKeys)
+	orderCols       []int          // columns to show, and in which order
+	selectCols      bool           // selecting columns instead of rows
+	marks           map[int]bool   // marked rows, keyed by original row index
+	groups          []rowGroup     // joined row ranges that move, sort, filter and search as a unit
+	commands        []*Command
+	legend          *tview.TextView
+	lastLine        tview.Primitive
+	inputCapture    func(k tcell.Key, r rune, row int) bool
 }
 
 type Application struct {
@@ -86,6 +99,11 @@ func (a *Application) NewTable() *TableView {
 	t.table.SetSeparator(tview.Borders.Vertical)
 	t.table.SetFixed(1, 0)
 	t.table.SetSelectable(true, false)
+	t.table.SetSelectionChangedFunc(func(row, col int) {
+		t.refreshPreview()
+	})
+	t.tableFlex = tview.NewFlex()
+	t.tableFlex.AddItem(t.table, 0, 1, true)
 	t.flex = tview.NewFlex()
 	t.legend = tview.NewTextView()
 	t.legend.SetBackgroundColor(tcell.ColorBlue)
@@ -114,6 +132,23 @@ func (a *Application) NewTable() *TableView {
 		case tcell.KeyESC:
 			t.app.app.Stop()
 			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if t.selectCols {
+				return event
+			}
+			row, _ := t.table.GetSelection()
+			if row >= 1 && row-1 < len(t.orderRows) {
+				t.toggleMark(t.orderRows[row-1])
+			}
+			if event.Key() == tcell.KeyTab {
+				if row < len(t.orderRows) {
+					t.table.Select(row+1, 0)
+				}
+			} else if row > 1 {
+				t.table.Select(row-1, 0)
+			}
+			t.fillTable()
+			return nil
 		case tcell.KeyRune:
 			if t.selectCols {
 				switch event.Rune() {
@@ -142,10 +177,7 @@ func (a *Application) NewTable() *TableView {
 					t.fillTable()
 				case 's':
 					_, col := t.table.GetSelection()
-					sort.Slice(t.orderRows, func(a, b int) bool {
-						return t.data[t.orderRows[a]][t.orderCols[col]] < t.data[t.orderRows[b]][t.orderCols[col]]
-					})
-					t.fillTable()
+					t.toggleSort(t.orderCols[col])
 				}
 				return event
 			}
@@ -209,6 +241,10 @@ func (a *Application) NewTable() *TableView {
 			case 'n':
 				row, _ := t.table.GetSelection()
 				t.search(row, lastSearch)
+			case 'J':
+				t.scrollPreview(1)
+			case 'K':
+				t.scrollPreview(-1)
 			case 'f':
 				row, _ := t.table.GetSelection()
 				row--
@@ -235,7 +271,11 @@ func (a *Application) NewTable() *TableView {
 			for _, c := range t.commands {
 				if c.enabled && event.Rune() == c.ch {
 					row, _ := t.table.GetSelection()
-					c.action(t.orderRows[row-1])
+					if c.bulk != nil {
+						c.bulk(t.markedOrCurrentRows(t.orderRows[row-1]))
+					} else {
+						c.action(t.orderRows[row-1])
+					}
 					t.fillTable()
 				}
 			}
@@ -245,7 +285,7 @@ func (a *Application) NewTable() *TableView {
 
 	t.flex.SetBackgroundColor(tcell.ColorRed)
 	t.flex.SetDirection(tview.FlexRow)
-	t.flex.AddItem(t.table, 0, 1, true)
+	t.flex.AddItem(t.tableFlex, 0, 1, true)
 	t.flex.AddItem(t.legend, 1, 0, false)
 	t.lastLine = tview.NewBox()
 	t.flex.AddItem(t.lastLine, 1, 0, false)
@@ -266,8 +306,10 @@ func NewTableView() *TableView {
 	return a.NewTable()
 }
 
-// FillTable populates a TableView with the given data
-func (t *TableView) FillTable(columns []string, data [][]string) {
+// FillCells populates a TableView with rich per-cell data.  Use this
+// instead of FillTable when cells need colors, attributes or a
+// Reference attached; see Cell.
+func (t *TableView) FillCells(columns []string, data [][]Cell) {
 	t.columns = columns
 	if len(t.expansions) < len(t.columns) {
 		t.expansions = append(t.expansions, make([]int, len(t.columns)-len(t.expansions))...)
@@ -280,6 +322,7 @@ func (t *TableView) FillTable(columns []string, data [][]string) {
 		for i := 0; i < len(t.columns); i++ {
 			t.orderCols[i] = i
 		}
+		t.sortKeys = nil
 	}
 	if len(data) != len(t.data) {
 		t.orderRows = make([]int, len(data))
@@ -287,6 +330,8 @@ func (t *TableView) FillTable(columns []string, data [][]string) {
 			t.orderRows[i] = i
 		}
 		t.filter = ""
+		t.marks = nil
+		t.groups = nil
 	}
 	t.data = data
 	t.fillTable()
@@ -294,8 +339,22 @@ func (t *TableView) FillTable(columns []string, data [][]string) {
 	t.table.SetOffset(0, 0)
 }
 
+// FillTable populates a TableView with plain string content.  It is a
+// convenience wrapper around FillCells for callers that don't need
+// per-cell styling or references.
+func (t *TableView) FillTable(columns []string, data [][]string) {
+	cells := make([][]Cell, len(data))
+	for i, row := range data {
+		cells[i] = make([]Cell, len(row))
+		for j, text := range row {
+			cells[i][j] = Cell{Text: text}
+		}
+	}
+	t.FillCells(columns, cells)
+}
+
 func (t *TableView) updateLegend() {
-	defaultMenu := " [yellow]q:quit   /:search   n:next   f:filter   c:columns"
+	defaultMenu := " [yellow]q:quit   /:search   n:next   f:filter   c:columns   tab:mark"
 	for _, c := range t.commands {
 		if c.enabled && c.text != "" {
 			defaultMenu = fmt.Sprintf("%s   %c:%s", defaultMenu, c.ch, c.text)
@@ -306,15 +365,50 @@ func (t *TableView) updateLegend() {
 
 func (t *TableView) fillTable() {
 	for i := 0; i < len(t.orderCols); i++ {
-		cell := tview.NewTableCell("[yellow]" + t.columns[t.orderCols[i]]).SetBackgroundColor(tcell.ColorBlue)
+		header := t.columns[t.orderCols[i]]
+		if idx := t.sortKeyIndex(t.orderCols[i]); idx >= 0 {
+			arrow := "▲"
+			if t.sortKeys[idx].desc {
+				arrow = "▼"
+			}
+			if idx > 0 {
+				header = fmt.Sprintf("%s %s%d", header, arrow, idx+1)
+			} else {
+				header = fmt.Sprintf("%s %s", header, arrow)
+			}
+		}
+		cell := tview.NewTableCell("[yellow]" + header).SetBackgroundColor(tcell.ColorBlue)
 		cell.SetSelectable(false)
 		t.table.SetCell(0, i, cell)
 		for j := 0; j < len(t.orderRows); j++ {
-			content := t.data[t.orderRows[j]][t.orderCols[i]]
+			orig := t.orderRows[j]
+			marked := t.marks[orig]
+			cellData := t.data[orig][t.orderCols[i]]
+			content := cellData.Text
+			if i == 0 {
+				glyph := " "
+				if rep, grouped := t.groupRepresentative(orig); grouped && rep != orig {
+					glyph = "│"
+				}
+				mark := " "
+				if marked {
+					mark = "*"
+				}
+				content = glyph + mark + content
+			}
 			cell := tview.NewTableCell(content)
 			cell.SetMaxWidth(32)
 			cell.SetExpansion(t.expansions[t.orderCols[i]])
 			cell.SetAlign(t.aligns[t.orderCols[i]])
+			if t.rowStyleFunc != nil {
+				cell.SetStyle(t.rowStyleFunc(orig))
+			}
+			if cellData.Style != nil {
+				cell.SetStyle(*cellData.Style)
+			}
+			if marked {
+				cell.SetBackgroundColor(tcell.ColorDarkSlateGray)
+			}
 			t.table.SetCell(j+1, i, cell)
 		}
 	}
@@ -328,16 +422,51 @@ func (t *TableView) fillTable() {
 
 func (t *TableView) filterData() {
 	t.orderRows = nil
-	text := strings.ToLower(t.filter)
-	for i := 0; i < len(t.data); i++ {
-		for j := 0; j < len(t.columns); j++ {
-			cellContent := strings.ToLower(t.data[i][j])
-			if strings.Contains(cellContent, text) {
-				t.orderRows = append(t.orderRows, i)
-				break
+	units := t.rowUnits()
+	if t.filter == "" {
+		for _, u := range units {
+			t.orderRows = append(t.orderRows, u.rows...)
+		}
+		t.sortRows()
+		t.fillTable()
+		return
+	}
+
+	type unitMatch struct {
+		unit  rowUnit
+		score int
+	}
+	var matches []unitMatch
+	for _, u := range units {
+		var ok bool
+		var score int
+		for _, row := range u.rows {
+			for j := 0; j < len(t.columns); j++ {
+				cellOK, cellScore := t.matchCell(t.filter, t.data[row][j].Text)
+				if !cellOK {
+					continue
+				}
+				ok = true
+				if cellScore > score {
+					score = cellScore
+				}
 			}
 		}
+		if ok {
+			matches = append(matches, unitMatch{u, score})
+		}
+	}
+
+	// sort.SliceStable keeps matches with equal scores in their original
+	// (insertion) order, so ties fall back to the original index.
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].score > matches[b].score
+	})
+	for _, m := range matches {
+		t.orderRows = append(t.orderRows, m.unit.rows...)
 	}
+	// An active column sort takes precedence over match-score ordering.
+	t.sortRows()
 	t.fillTable()
 }
 
@@ -354,12 +483,12 @@ func (t *TableView) SetCell(row int, column int, content string) {
 		for i := len(t.data); i < row+1; i++ {
 			t.orderRows[i] = i
 		}
-		t.data = append(t.data, make([][]string, row-len(t.data)+1)...)
+		t.data = append(t.data, make([][]Cell, row-len(t.data)+1)...)
 	}
 	if column > len(t.data[row])-1 {
-		t.data[row] = append(t.data[row], make([]string, column-len(t.data[row])+1)...)
+		t.data[row] = append(t.data[row], make([]Cell, column-len(t.data[row])+1)...)
 	}
-	t.data[row][column] = content
+	t.data[row][column].Text = content
 }
 
 // SetExpansion sets the value by which the column expands if the
@@ -471,23 +600,45 @@ func (t *TableView) updateLastLine() {
 }
 
 func (t *TableView) search(startRow int, text string) bool {
-	text = strings.ToLower(text)
+	best := -1
+	bestScore := -1
 	for i := 0; i < len(t.orderRows); i++ {
+		idx := (startRow + i) % len(t.orderRows)
+		row := t.orderRows[idx]
+		var ok bool
+		var score int
 		for j := 0; j < len(t.columns); j++ {
-			cellContent := strings.ToLower(t.data[t.orderRows[(startRow+i)%len(t.orderRows)]][j])
-			if strings.Contains(cellContent, text) {
-				t.table.Select(((startRow+i)%len(t.orderRows))+1, 0)
-				return true
+			cellOK, cellScore := t.matchCell(text, t.data[row][j].Text)
+			if !cellOK {
+				continue
+			}
+			ok = true
+			if cellScore > score {
+				score = cellScore
 			}
 		}
+		if !ok {
+			continue
+		}
+		// Jump to the group's representative, not whichever member
+		// happened to match.
+		target := t.representativePosition(idx, row)
+		if t.matchMode != MatchFuzzy {
+			// Substring and regex matches aren't scored: jump to the
+			// first one found, same as before.
+			t.table.Select(target+1, 0)
+			return true
+		}
+		if score > bestScore {
+			bestScore = score
+			best = target
+		}
 	}
-	return false
-}
-
-// JoinRows marks several rows to be always together, and with the same visibility.
-// This affects the behaviour of t.search(), t.filterData() and t.sort()
-func (t *TableView) JoinRows(startRow int, endRow int) error {
-	return fmt.Errorf("not implemented")
+	if best == -1 {
+		return false
+	}
+	t.table.Select(best+1, 0)
+	return true
 }
 
 // Run draws the table and starts a loop, waiting for keystrokes